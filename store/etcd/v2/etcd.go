@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +35,12 @@ func Register() {
 // Etcd is the receiver type for the Store interface.
 type Etcd struct {
 	client etcd.KeysAPI
+
+	// watchErrs records, per Watch/WatchTree call (keyed by its stopCh), the
+	// terminal error that closed its channel — most notably ErrWatchCompacted.
+	// Watch/WatchTree can only report an error synchronously before the
+	// channel starts, so this is how a caller learns why it later closed.
+	watchErrs sync.Map
 }
 
 // New creates a new Etcd client given a list of endpoints and an optional TLS config.
@@ -166,6 +173,28 @@ func (s *Etcd) Delete(key string) error {
 	return err
 }
 
+// WatchErr returns the error that terminated the Watch/WatchTree channel
+// started with stopCh, most notably store.ErrWatchCompacted when the
+// requested starting index fell out of etcd's history. It returns nil if
+// the channel is still running, was stopped via stopCh, or closed for any
+// other reason. Safe to call once the channel has been drained.
+func (s *Etcd) WatchErr(stopCh <-chan struct{}) error {
+	v, ok := s.watchErrs.Load(stopCh)
+	if !ok {
+		return nil
+	}
+	return v.(error)
+}
+
+// watchTerminal records store.ErrWatchCompacted against stopCh when err is
+// the "requested index cleared from history" error, so WatchErr can later
+// report it to the caller.
+func (s *Etcd) watchTerminal(err error, stopCh <-chan struct{}) {
+	if watchCompacted(err) {
+		s.watchErrs.Store(stopCh, store.ErrWatchCompacted)
+	}
+}
+
 // Exists checks if the key exists inside the store.
 func (s *Etcd) Exists(key string, opts *store.ReadOptions) (bool, error) {
 	_, err := s.Get(key, opts)
@@ -180,26 +209,43 @@ func (s *Etcd) Exists(key string, opts *store.ReadOptions) (bool, error) {
 
 // Watch for changes on a "key".
 // It returns a channel that will receive changes or pass on errors.
-// Upon creation, the current value will first be sent to the channel.
+// Upon creation, the current value will first be sent to the channel,
+// unless opts.StartIndex is set, in which case the watch resumes right
+// after that index instead of sending an initial snapshot.
 // Providing a non-nil stopCh can be used to stop watching.
 func (s *Etcd) Watch(key string, stopCh <-chan struct{}, opts *store.ReadOptions) (<-chan *store.KVPair, error) {
 	wopts := &etcd.WatcherOptions{Recursive: false}
+
+	resuming := opts != nil && opts.StartIndex != 0
+	if resuming {
+		wopts.AfterIndex = opts.StartIndex
+	}
+
 	watcher := s.client.Watcher(s.normalize(key), wopts)
 
+	// When not resuming, fetch the current value up front so it can be
+	// pushed through the channel before Watch returns. When resuming, the
+	// replay is read from the watcher itself, which may block waiting for
+	// the next change if the caller is already caught up — that read is
+	// done in the background goroutine below so Watch always returns promptly.
+	var pair *store.KVPair
+	if !resuming {
+		var err error
+		pair, err = s.Get(key, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// watchCh is sending back events to the caller.
 	watchCh := make(chan *store.KVPair)
 
-	// Get the current value.
-	pair, err := s.Get(key, opts)
-	if err != nil {
-		return nil, err
-	}
-
 	go func() {
 		defer close(watchCh)
 
-		// Push the current value through the channel.
-		watchCh <- pair
+		if !resuming {
+			watchCh <- pair
+		}
 
 		for {
 			// Check if the watch was stopped by the caller.
@@ -211,6 +257,11 @@ func (s *Etcd) Watch(key string, stopCh <-chan struct{}, opts *store.ReadOptions
 
 			result, err := watcher.Next(context.Background())
 			if err != nil {
+				// Like any other terminal watch error, there is nothing more
+				// we can send on this channel; watchTerminal records
+				// ErrWatchCompacted if that's why, so the caller can query
+				// WatchErr and decide to resync from scratch.
+				s.watchTerminal(err, stopCh)
 				return
 			}
 
@@ -227,25 +278,59 @@ func (s *Etcd) Watch(key string, stopCh <-chan struct{}, opts *store.ReadOptions
 
 // WatchTree watches for changes on a "directory".
 // It returns a channel that will receive changes or pass on errors.
-// Upon creating a watch, the current children values will be sent to the channel.
+// Upon creating a watch, the current children values will be sent to the channel,
+// unless opts.StartIndex is set, in which case the watch resumes right
+// after that index instead of sending an initial snapshot.
 // Providing a non-nil stopCh can be used to stop watching.
 func (s *Etcd) WatchTree(directory string, stopCh <-chan struct{}, opts *store.ReadOptions) (<-chan []*store.KVPair, error) {
 	watchOpts := &etcd.WatcherOptions{Recursive: true}
+
+	resuming := opts != nil && opts.StartIndex != 0
+	if resuming {
+		watchOpts.AfterIndex = opts.StartIndex
+	}
+
 	watcher := s.client.Watcher(s.normalize(directory), watchOpts)
 
+	// When not resuming, list the current children up front so they can be
+	// pushed through the channel before WatchTree returns. When resuming, the
+	// replay is read from the watcher itself, which may block waiting for
+	// the next change if the caller is already caught up — that read is
+	// done in the background goroutine below so WatchTree always returns promptly.
+	var list []*store.KVPair
+	if !resuming {
+		var err error
+		list, err = s.List(directory, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// watchCh is sending back events to the caller.
 	watchCh := make(chan []*store.KVPair)
 
-	// List current children.
-	list, err := s.List(directory, opts)
-	if err != nil {
-		return nil, err
-	}
-
 	go func() {
 		defer close(watchCh)
 
-		// Push the current value through the channel.
+		if resuming {
+			_, err := watcher.Next(context.Background())
+			if err != nil {
+				// Like any other terminal watch error, there is nothing more
+				// we can send on this channel; watchTerminal records
+				// ErrWatchCompacted if that's why, so the caller can query
+				// WatchErr and decide to resync from scratch.
+				s.watchTerminal(err, stopCh)
+				return
+			}
+
+			children, err := s.List(directory, opts)
+			if err != nil {
+				return
+			}
+			list = children
+		}
+
+		// Push the current (or replayed) value through the channel.
 		watchCh <- list
 
 		for {
@@ -258,13 +343,15 @@ func (s *Etcd) WatchTree(directory string, stopCh <-chan struct{}, opts *store.R
 
 			_, err := watcher.Next(context.Background())
 			if err != nil {
+				s.watchTerminal(err, stopCh)
 				return
 			}
 
-			list, err = s.List(directory, opts)
+			children, err := s.List(directory, opts)
 			if err != nil {
 				return
 			}
+			list = children
 
 			watchCh <- list
 		}
@@ -349,6 +436,11 @@ func (s *Etcd) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
 	return true, nil
 }
 
+// AtomicBatch is not supported by the etcd v2 backend.
+func (s *Etcd) AtomicBatch(ops []store.TxnOp) (bool, []*store.KVPair, error) {
+	return false, nil, store.ErrCallNotSupported
+}
+
 // List child nodes of a given directory.
 func (s *Etcd) List(directory string, opts *store.ReadOptions) ([]*store.KVPair, error) {
 	getOpts := &etcd.GetOptions{
@@ -413,6 +505,71 @@ func (s *Etcd) DeleteTree(directory string) error {
 	return err
 }
 
+// paginate applies a ListOptions' ordering, cursor and limit to an
+// already-fetched set of pairs, client-side.
+func paginate(kv []*store.KVPair, opts *store.ListOptions) []*store.KVPair {
+	descending := opts != nil && opts.SortOrder == store.SortDescend
+
+	if descending {
+		sort.Slice(kv, func(i, j int) bool { return kv[i].Key > kv[j].Key })
+	} else {
+		sort.Slice(kv, func(i, j int) bool { return kv[i].Key < kv[j].Key })
+	}
+
+	if opts == nil {
+		return kv
+	}
+
+	if opts.StartAfterKey != "" {
+		filtered := kv[:0:0]
+		for _, pair := range kv {
+			// Walking descending means keys after the cursor are the smaller ones.
+			if (descending && pair.Key < opts.StartAfterKey) || (!descending && pair.Key > opts.StartAfterKey) {
+				filtered = append(filtered, pair)
+			}
+		}
+		kv = filtered
+	}
+
+	if opts.Limit > 0 && int64(len(kv)) > opts.Limit {
+		kv = kv[:opts.Limit]
+	}
+
+	if opts.KeysOnly {
+		for _, pair := range kv {
+			pair.Value = nil
+		}
+	}
+
+	return kv
+}
+
+// ListPrefix lists the keys under a given prefix, with optional pagination.
+// Etcd v2 has no native range API, so prefix is treated as a directory and
+// the requested ordering/pagination is applied client-side on top of List.
+func (s *Etcd) ListPrefix(prefix string, opts *store.ListOptions) ([]*store.KVPair, error) {
+	kv, err := s.List(prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(kv, opts), nil
+}
+
+// DeletePrefix deletes every key under a given prefix.
+func (s *Etcd) DeletePrefix(prefix string) error {
+	return s.DeleteTree(prefix)
+}
+
+// Count returns the number of keys under a given prefix.
+func (s *Etcd) Count(prefix string) (int64, error) {
+	kv, err := s.List(prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(kv)), nil
+}
+
 // NewLock returns a handle to a lock struct
 // which can be used to provide mutual exclusion on a key.
 func (s *Etcd) NewLock(key string, options *store.LockOptions) (lock store.Locker, err error) {
@@ -619,3 +776,14 @@ func keyNotFound(err error) bool {
 	}
 	return false
 }
+
+// watchCompacted checks on the error returned by the Watcher
+// to verify if the requested index has been cleared from etcd's event history.
+func watchCompacted(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	etcdError, ok := err.(etcd.Error)
+	return ok && etcdError.Code == etcd.ErrorCodeEventIndexCleared
+}
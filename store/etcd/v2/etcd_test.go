@@ -0,0 +1,82 @@
+package etcd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kvtools/valkeyrie/store"
+	etcd "go.etcd.io/etcd/client/v2"
+)
+
+func pairsForKeys(keys ...string) []*store.KVPair {
+	kv := make([]*store.KVPair, 0, len(keys))
+	for _, key := range keys {
+		kv = append(kv, &store.KVPair{Key: key})
+	}
+	return kv
+}
+
+func keysOf(kv []*store.KVPair) []string {
+	keys := make([]string, 0, len(kv))
+	for _, pair := range kv {
+		keys = append(keys, pair.Key)
+	}
+	return keys
+}
+
+func TestPaginateAscendingCursor(t *testing.T) {
+	kv := pairsForKeys("a", "b", "c", "d", "e")
+
+	got := keysOf(paginate(kv, &store.ListOptions{StartAfterKey: "b"}))
+
+	want := []string{"c", "d", "e"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWatchTerminalRecordsCompaction(t *testing.T) {
+	s := &Etcd{}
+	stopCh := make(chan struct{})
+
+	s.watchTerminal(etcd.Error{Code: etcd.ErrorCodeEventIndexCleared}, stopCh)
+
+	if err := s.WatchErr(stopCh); err != store.ErrWatchCompacted {
+		t.Fatalf("expected ErrWatchCompacted, got %v", err)
+	}
+}
+
+func TestWatchTerminalIgnoresOtherErrors(t *testing.T) {
+	s := &Etcd{}
+	stopCh := make(chan struct{})
+
+	s.watchTerminal(errors.New("boom"), stopCh)
+
+	if err := s.WatchErr(stopCh); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestPaginateDescendingCursor(t *testing.T) {
+	kv := pairsForKeys("e", "d", "c", "b", "a")
+
+	got := keysOf(paginate(kv, &store.ListOptions{
+		StartAfterKey: "c",
+		SortOrder:     store.SortDescend,
+	}))
+
+	want := []string{"b", "a"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package etcdv3
+
+import (
+	"context"
+
+	"github.com/kvtools/valkeyrie/store"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// NewElector returns a handle to an Elector struct which can be used to
+// run a leader election on "key".
+func (s *EtcdV3) NewElector(key string) (store.Elector, error) {
+	session, err := concurrency.NewSession(s.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &etcdElector{
+		session:  session,
+		election: concurrency.NewElection(session, s.normalize(key)),
+	}, nil
+}
+
+// etcdElector implements store.Elector on top of clientv3's concurrency.Election,
+// which itself is backed by a session-bound lease: candidates put their value
+// under the election prefix keyed by their lease ID, and the one holding the
+// smallest CreateRevision under that prefix is the leader.
+type etcdElector struct {
+	session  *concurrency.Session
+	election *concurrency.Election
+}
+
+// Campaign puts the candidate's value forward for election and blocks
+// until it becomes the leader. It returns a channel that is closed
+// when leadership is lost, i.e. when the underlying session expires.
+func (e *etcdElector) Campaign(ctx context.Context, value []byte) (<-chan struct{}, error) {
+	if err := e.election.Campaign(ctx, string(value)); err != nil {
+		return nil, err
+	}
+	return e.session.Done(), nil
+}
+
+// Resign gives up leadership, if held, allowing the next candidate to be elected.
+// The session backing the Elector stays open, so the same Elector can Campaign again.
+func (e *etcdElector) Resign(ctx context.Context) error {
+	return e.election.Resign(ctx)
+}
+
+// Close releases the session (and its lease/keepalive goroutine) backing this
+// Elector. Call it once the caller is done campaigning, even after Resign.
+func (e *etcdElector) Close() error {
+	return e.session.Close()
+}
+
+// Leader returns the current leader's KVPair, or store.ErrKeyNotFound if there is none.
+func (e *etcdElector) Leader(ctx context.Context) (*store.KVPair, error) {
+	resp, err := e.election.Leader(ctx)
+	if err != nil {
+		if err == concurrency.ErrElectionNoLeader {
+			return nil, store.ErrKeyNotFound
+		}
+		return nil, err
+	}
+
+	kv := resp.Kvs[0]
+	return &store.KVPair{
+		Key:       string(kv.Key),
+		Value:     kv.Value,
+		LastIndex: uint64(kv.ModRevision),
+	}, nil
+}
+
+// Observe streams the leader's KVPair every time it changes.
+func (e *etcdElector) Observe(ctx context.Context) <-chan *store.KVPair {
+	leaderCh := make(chan *store.KVPair)
+
+	go func() {
+		defer close(leaderCh)
+
+		for resp := range e.election.Observe(ctx) {
+			if len(resp.Kvs) == 0 {
+				continue
+			}
+
+			kv := resp.Kvs[0]
+			select {
+			case leaderCh <- &store.KVPair{
+				Key:       string(kv.Key),
+				Value:     kv.Value,
+				LastIndex: uint64(kv.ModRevision),
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return leaderCh
+}
@@ -0,0 +1,476 @@
+// Package etcdv3 contains the etcd v3 store implementation.
+package etcdv3
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvtools/valkeyrie"
+	"github.com/kvtools/valkeyrie/store"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Register registers etcd v3 to valkeyrie.
+func Register() {
+	valkeyrie.AddStore(store.ETCDV3, New)
+}
+
+// EtcdV3 is the receiver type for the Store interface.
+type EtcdV3 struct {
+	client *clientv3.Client
+
+	// watchErrs records, per Watch/WatchTree call (keyed by its stopCh), the
+	// terminal error that closed its channel — most notably ErrWatchCompacted.
+	// Watch/WatchTree can only report an error synchronously before the
+	// channel starts, so this is how a caller learns why it later closed.
+	watchErrs sync.Map
+}
+
+// New creates a new EtcdV3 client given a list of endpoints and an optional TLS config.
+func New(addrs []string, options *store.Config) (store.Store, error) {
+	cfg := clientv3.Config{
+		Endpoints:   addrs,
+		DialTimeout: 5 * time.Second,
+	}
+
+	// Set options.
+	if options != nil {
+		if options.TLS != nil {
+			setTLS(&cfg, options.TLS)
+		}
+		if options.ConnectionTimeout != 0 {
+			cfg.DialTimeout = options.ConnectionTimeout
+		}
+		if options.Username != "" {
+			cfg.Username = options.Username
+			cfg.Password = options.Password
+		}
+	}
+
+	c, err := clientv3.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EtcdV3{client: c}, nil
+}
+
+// setTLS sets the tls configuration given a tls.Config scheme.
+func setTLS(cfg *clientv3.Config, tlsCfg *tls.Config) {
+	cfg.TLS = tlsCfg
+}
+
+// normalize the key for usage in etcd.
+func (s *EtcdV3) normalize(key string) string {
+	return strings.TrimPrefix(store.Normalize(key), "/")
+}
+
+// Get the value at "key".
+// Returns the last modified index (ModRevision) to use in conjunction with Atomic calls.
+func (s *EtcdV3) Get(key string, opts *store.ReadOptions) (*store.KVPair, error) {
+	resp, err := s.client.Get(context.Background(), s.normalize(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+
+	kv := resp.Kvs[0]
+	return &store.KVPair{
+		Key:       key,
+		Value:     kv.Value,
+		LastIndex: uint64(kv.ModRevision),
+	}, nil
+}
+
+// Put a value at "key".
+func (s *EtcdV3) Put(key string, value []byte, opts *store.WriteOptions) error {
+	putOpts, err := s.ttlOpts(opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Put(context.Background(), s.normalize(key), string(value), putOpts...)
+	return err
+}
+
+// ttlOpts acquires a lease and returns the clientv3 options to attach it to a Put, if a TTL is set.
+func (s *EtcdV3) ttlOpts(opts *store.WriteOptions) ([]clientv3.OpOption, error) {
+	if opts == nil || opts.TTL == 0 {
+		return nil, nil
+	}
+
+	lease, err := s.client.Grant(context.Background(), int64(opts.TTL.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	return []clientv3.OpOption{clientv3.WithLease(lease.ID)}, nil
+}
+
+// Delete a value at "key".
+func (s *EtcdV3) Delete(key string) error {
+	resp, err := s.client.Delete(context.Background(), s.normalize(key))
+	if err != nil {
+		return err
+	}
+	if resp.Deleted == 0 {
+		return store.ErrKeyNotFound
+	}
+	return nil
+}
+
+// WatchErr returns the error that terminated the Watch/WatchTree channel
+// started with stopCh, most notably store.ErrWatchCompacted when the
+// requested starting revision fell out of etcd's history. It returns nil if
+// the channel is still running, was stopped via stopCh, or closed for any
+// other reason. Safe to call once the channel has been drained.
+func (s *EtcdV3) WatchErr(stopCh <-chan struct{}) error {
+	v, ok := s.watchErrs.Load(stopCh)
+	if !ok {
+		return nil
+	}
+	return v.(error)
+}
+
+// watchCanceled reports whether resp terminates the watch, recording
+// store.ErrWatchCompacted against stopCh (queryable via WatchErr) when the
+// cancellation was caused by the requested revision being compacted out of
+// etcd's history rather than some other cancellation.
+func (s *EtcdV3) watchCanceled(resp clientv3.WatchResponse, stopCh <-chan struct{}) bool {
+	if !resp.Canceled {
+		return false
+	}
+	if resp.CompactRevision != 0 {
+		s.watchErrs.Store(stopCh, store.ErrWatchCompacted)
+	}
+	return true
+}
+
+// Exists checks if the key exists inside the store.
+func (s *EtcdV3) Exists(key string, opts *store.ReadOptions) (bool, error) {
+	resp, err := s.client.Get(context.Background(), s.normalize(key), clientv3.WithCountOnly())
+	if err != nil {
+		return false, err
+	}
+	return resp.Count > 0, nil
+}
+
+// Watch for changes on a "key".
+// It returns a channel that will receive changes; on a watch error the
+// channel is closed, and WatchErr reports a compaction.
+// Upon creation, the current value will first be sent to the channel,
+// unless opts.StartRevision is set, in which case the watch resumes right
+// after that revision instead of sending an initial snapshot.
+// Providing a non-nil stopCh can be used to stop watching.
+func (s *EtcdV3) Watch(key string, stopCh <-chan struct{}, opts *store.ReadOptions) (<-chan *store.KVPair, error) {
+	normalizedKey := s.normalize(key)
+
+	var watchOpts []clientv3.OpOption
+	resuming := opts != nil && opts.StartRevision != 0
+	if resuming {
+		watchOpts = append(watchOpts, clientv3.WithRev(opts.StartRevision+1))
+	}
+
+	watcher := s.client.Watch(context.Background(), normalizedKey, watchOpts...)
+
+	// When not resuming, fetch the current value up front so it can be
+	// pushed through the channel before Watch returns. When resuming, the
+	// replay is read from the watcher itself, which may block waiting for
+	// the next change if the caller is already caught up — that read is
+	// done in the background goroutine below so Watch always returns promptly.
+	var pair *store.KVPair
+	if !resuming {
+		var err error
+		pair, err = s.Get(key, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	watchCh := make(chan *store.KVPair)
+
+	go func() {
+		defer close(watchCh)
+
+		if !resuming {
+			watchCh <- pair
+		}
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case resp, ok := <-watcher:
+				if !ok {
+					return
+				}
+				// Like any other terminal watch error, there is nothing more
+				// we can send on this channel; watchCanceled records
+				// ErrWatchCompacted if that's why, so the caller can query
+				// WatchErr and decide to resync from scratch.
+				if s.watchCanceled(resp, stopCh) {
+					return
+				}
+				if resp.Err() != nil {
+					return
+				}
+
+				for _, event := range resp.Events {
+					watchCh <- eventToKVPair(key, event)
+				}
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+// WatchTree watches for changes on a "directory".
+// It returns a channel that will receive changes; on a watch error the
+// channel is closed, and WatchErr reports a compaction.
+// Upon creating a watch, the current children values will be sent to the channel,
+// unless opts.StartRevision is set, in which case the watch resumes right
+// after that revision instead of sending an initial snapshot.
+// Providing a non-nil stopCh can be used to stop watching.
+func (s *EtcdV3) WatchTree(directory string, stopCh <-chan struct{}, opts *store.ReadOptions) (<-chan []*store.KVPair, error) {
+	normalizedDir := s.normalize(directory)
+
+	watchOpts := []clientv3.OpOption{clientv3.WithPrefix()}
+	resuming := opts != nil && opts.StartRevision != 0
+	if resuming {
+		watchOpts = append(watchOpts, clientv3.WithRev(opts.StartRevision+1))
+	}
+
+	watcher := s.client.Watch(context.Background(), normalizedDir, watchOpts...)
+
+	// When not resuming, list the current children up front so they can be
+	// pushed through the channel before WatchTree returns. When resuming, the
+	// replay is read from the watcher itself, which may block waiting for
+	// the next change if the caller is already caught up — that read is
+	// done in the background goroutine below so WatchTree always returns promptly.
+	var list []*store.KVPair
+	if !resuming {
+		var err error
+		list, err = s.List(directory, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	watchCh := make(chan []*store.KVPair)
+
+	go func() {
+		defer close(watchCh)
+
+		if resuming {
+			resp, ok := <-watcher
+			if !ok {
+				return
+			}
+			// Like any other terminal watch error, there is nothing more we
+			// can send on this channel; watchCanceled records
+			// ErrWatchCompacted if that's why, so the caller can query
+			// WatchErr and decide to resync from scratch.
+			if s.watchCanceled(resp, stopCh) {
+				return
+			}
+
+			children, err := s.List(directory, opts)
+			if err != nil {
+				return
+			}
+			list = children
+		}
+
+		watchCh <- list
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case resp, ok := <-watcher:
+				if !ok {
+					return
+				}
+				if s.watchCanceled(resp, stopCh) {
+					return
+				}
+
+				newList, err := s.List(directory, opts)
+				if err != nil {
+					return
+				}
+				list = newList
+
+				watchCh <- list
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+// eventToKVPair translates a clientv3 watch event into a store.KVPair.
+// A DELETE event carries a nil value.
+func eventToKVPair(key string, event *clientv3.Event) *store.KVPair {
+	if event.Type == clientv3.EventTypeDelete {
+		return &store.KVPair{
+			Key:       key,
+			LastIndex: uint64(event.Kv.ModRevision),
+		}
+	}
+
+	return &store.KVPair{
+		Key:       key,
+		Value:     event.Kv.Value,
+		LastIndex: uint64(event.Kv.ModRevision),
+	}
+}
+
+// AtomicPut puts a value at "key" if the key has not been modified in the meantime,
+// throws an error if this is the case.
+func (s *EtcdV3) AtomicPut(key string, value []byte, previous *store.KVPair, opts *store.WriteOptions) (bool, *store.KVPair, error) {
+	normalizedKey := s.normalize(key)
+
+	putOpts, err := s.ttlOpts(opts)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var cmp clientv3.Cmp
+	if previous == nil {
+		cmp = clientv3.Compare(clientv3.CreateRevision(normalizedKey), "=", 0)
+	} else {
+		cmp = clientv3.Compare(clientv3.ModRevision(normalizedKey), "=", int64(previous.LastIndex))
+	}
+
+	resp, err := s.client.Txn(context.Background()).
+		If(cmp).
+		Then(clientv3.OpPut(normalizedKey, string(value), putOpts...)).
+		Commit()
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !resp.Succeeded {
+		if previous == nil {
+			return false, nil, store.ErrKeyExists
+		}
+		return false, nil, store.ErrKeyModified
+	}
+
+	updated, err := s.Get(key, nil)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, updated, nil
+}
+
+// AtomicDelete deletes a value at "key" if the key has not been modified in the meantime,
+// throws an error if this is the case.
+func (s *EtcdV3) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
+	if previous == nil {
+		return false, store.ErrPreviousNotSpecified
+	}
+
+	normalizedKey := s.normalize(key)
+
+	resp, err := s.client.Txn(context.Background()).
+		If(clientv3.Compare(clientv3.ModRevision(normalizedKey), "=", int64(previous.LastIndex))).
+		Then(clientv3.OpDelete(normalizedKey)).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+
+	if !resp.Succeeded {
+		exists, err := s.Exists(key, nil)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			return false, store.ErrKeyNotFound
+		}
+		return false, store.ErrKeyModified
+	}
+
+	return true, nil
+}
+
+// List child nodes of a given directory.
+func (s *EtcdV3) List(directory string, opts *store.ReadOptions) ([]*store.KVPair, error) {
+	resp, err := s.client.Get(context.Background(), s.normalize(directory), clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+
+	kv := make([]*store.KVPair, 0, len(resp.Kvs))
+	for _, n := range resp.Kvs {
+		if string(n.Key) == s.normalize(directory) {
+			continue
+		}
+
+		kv = append(kv, &store.KVPair{
+			Key:       string(n.Key),
+			Value:     n.Value,
+			LastIndex: uint64(n.ModRevision),
+		})
+	}
+	return kv, nil
+}
+
+// DeleteTree deletes a range of keys under a given directory.
+func (s *EtcdV3) DeleteTree(directory string) error {
+	resp, err := s.client.Delete(context.Background(), s.normalize(directory), clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	if resp.Deleted == 0 {
+		return store.ErrKeyNotFound
+	}
+	return nil
+}
+
+// NewLock returns a handle to a lock struct
+// which can be used to provide mutual exclusion on a key.
+func (s *EtcdV3) NewLock(key string, options *store.LockOptions) (store.Locker, error) {
+	ttl := defaultLockTTL
+	renewCh := make(chan struct{})
+
+	var value []byte
+	if options != nil {
+		if options.Value != nil {
+			value = options.Value
+		}
+		if options.TTL != 0 {
+			ttl = options.TTL
+		}
+		if options.RenewLock != nil {
+			renewCh = options.RenewLock
+		}
+	}
+
+	return &etcdLock{
+		client:    s.client,
+		stopRenew: renewCh,
+		key:       s.normalize(key),
+		value:     value,
+		ttl:       ttl,
+	}, nil
+}
+
+// Close closes the client connection.
+func (s *EtcdV3) Close() {
+	_ = s.client.Close()
+}
@@ -0,0 +1,45 @@
+package etcdv3
+
+import (
+	"testing"
+
+	"github.com/kvtools/valkeyrie/store"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func TestWatchCanceledRecordsCompaction(t *testing.T) {
+	s := &EtcdV3{}
+	stopCh := make(chan struct{})
+
+	canceled := s.watchCanceled(clientv3.WatchResponse{Canceled: true, CompactRevision: 5}, stopCh)
+
+	if !canceled {
+		t.Fatalf("expected the response to be reported canceled")
+	}
+	if err := s.WatchErr(stopCh); err != store.ErrWatchCompacted {
+		t.Fatalf("expected ErrWatchCompacted, got %v", err)
+	}
+}
+
+func TestWatchCanceledIgnoresPlainCancellation(t *testing.T) {
+	s := &EtcdV3{}
+	stopCh := make(chan struct{})
+
+	canceled := s.watchCanceled(clientv3.WatchResponse{Canceled: true}, stopCh)
+
+	if !canceled {
+		t.Fatalf("expected the response to be reported canceled")
+	}
+	if err := s.WatchErr(stopCh); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestWatchCanceledIgnoresNonCanceledResponse(t *testing.T) {
+	s := &EtcdV3{}
+	stopCh := make(chan struct{})
+
+	if s.watchCanceled(clientv3.WatchResponse{}, stopCh) {
+		t.Fatalf("expected a non-canceled response to report false")
+	}
+}
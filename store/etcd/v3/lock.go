@@ -0,0 +1,94 @@
+package etcdv3
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+const defaultLockTTL = 20 * time.Second
+
+// etcdLock implements store.Locker on top of clientv3's concurrency.Mutex,
+// bound to a lease-backed concurrency.Session.
+type etcdLock struct {
+	lock sync.Mutex
+
+	client *clientv3.Client
+
+	stopRenew chan struct{}
+
+	key   string
+	value []byte
+	ttl   time.Duration
+
+	session *concurrency.Session
+	mutex   *concurrency.Mutex
+}
+
+// Lock attempts to acquire the lock and blocks while doing so.
+// It returns a channel that is closed if our lock is lost or if an error occurs.
+func (l *etcdLock) Lock(stopChan chan struct{}) (<-chan struct{}, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	session, err := concurrency.NewSession(l.client, concurrency.WithTTL(int(l.ttl.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+
+	mutex := concurrency.NewMutex(session, l.key)
+
+	if err := mutex.Lock(context.Background()); err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+
+	if l.value != nil {
+		if _, err := l.client.Put(context.Background(), mutex.Key(), string(l.value), clientv3.WithLease(session.Lease())); err != nil {
+			_ = mutex.Unlock(context.Background())
+			_ = session.Close()
+			return nil, err
+		}
+	}
+
+	l.session = session
+	l.mutex = mutex
+
+	lockHeld := make(chan struct{})
+	go func() {
+		defer close(lockHeld)
+
+		select {
+		case <-l.stopRenew:
+			// Unlike session.Done() (the lease expired on its own) or
+			// stopChan (the caller is about to call Unlock itself), nothing
+			// else will release the lock here, so do it ourselves. Otherwise
+			// the session's keepalive keeps renewing the lease forever while
+			// the caller believes the lock is gone.
+			_ = mutex.Unlock(context.Background())
+			_ = session.Close()
+		case <-session.Done():
+		case <-stopChan:
+		}
+	}()
+
+	return lockHeld, nil
+}
+
+// Unlock the lock.
+// Calling Unlock before Lock has succeeded does nothing and returns nil.
+func (l *etcdLock) Unlock() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.mutex == nil {
+		return nil
+	}
+
+	err := l.mutex.Unlock(context.Background())
+	_ = l.session.Close()
+	return err
+}
@@ -0,0 +1,89 @@
+package etcdv3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kvtools/valkeyrie/store"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// cursorRange computes the [startKey, rangeEnd) bounds for ListPrefix,
+// accounting for the requested SortOrder: walking descending means the keys
+// after a cursor are the smaller ones still within the prefix.
+func cursorRange(normalizedPrefix string, opts *store.ListOptions) (startKey, rangeEnd string) {
+	startKey = normalizedPrefix
+	rangeEnd = clientv3.GetPrefixRangeEnd(normalizedPrefix)
+
+	if opts == nil || opts.StartAfterKey == "" {
+		return startKey, rangeEnd
+	}
+
+	cursor := strings.TrimPrefix(store.Normalize(opts.StartAfterKey), "/")
+	if opts.SortOrder == store.SortDescend {
+		rangeEnd = cursor
+	} else {
+		// Exclude the cursor key itself by starting right after it.
+		startKey = cursor + "\x00"
+	}
+	return startKey, rangeEnd
+}
+
+// ListPrefix lists the keys under a given prefix, with optional pagination.
+func (s *EtcdV3) ListPrefix(prefix string, opts *store.ListOptions) ([]*store.KVPair, error) {
+	normalizedPrefix := s.normalize(prefix)
+	startKey, rangeEnd := cursorRange(normalizedPrefix, opts)
+
+	getOpts := []clientv3.OpOption{clientv3.WithRange(rangeEnd)}
+
+	if opts != nil {
+		if opts.Limit > 0 {
+			getOpts = append(getOpts, clientv3.WithLimit(opts.Limit))
+		}
+		if opts.KeysOnly {
+			getOpts = append(getOpts, clientv3.WithKeysOnly())
+		}
+		switch opts.SortOrder {
+		case store.SortAscend:
+			getOpts = append(getOpts, clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+		case store.SortDescend:
+			getOpts = append(getOpts, clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend))
+		}
+	}
+
+	resp, err := s.client.Get(context.Background(), startKey, getOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	kv := make([]*store.KVPair, 0, len(resp.Kvs))
+	for _, n := range resp.Kvs {
+		kv = append(kv, &store.KVPair{
+			Key:       string(n.Key),
+			Value:     n.Value,
+			LastIndex: uint64(n.ModRevision),
+		})
+	}
+	return kv, nil
+}
+
+// DeletePrefix deletes every key under a given prefix.
+func (s *EtcdV3) DeletePrefix(prefix string) error {
+	resp, err := s.client.Delete(context.Background(), s.normalize(prefix), clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	if resp.Deleted == 0 {
+		return store.ErrKeyNotFound
+	}
+	return nil
+}
+
+// Count returns the number of keys under a given prefix.
+func (s *EtcdV3) Count(prefix string) (int64, error) {
+	resp, err := s.client.Get(context.Background(), s.normalize(prefix), clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
@@ -0,0 +1,43 @@
+package etcdv3
+
+import (
+	"testing"
+
+	"github.com/kvtools/valkeyrie/store"
+)
+
+func TestCursorRangeAscending(t *testing.T) {
+	startKey, rangeEnd := cursorRange("prefix", &store.ListOptions{StartAfterKey: "prefix/b"})
+
+	if startKey != "prefix/b\x00" {
+		t.Fatalf("expected startKey right after the cursor, got %q", startKey)
+	}
+	if rangeEnd != "prefiy" {
+		t.Fatalf("expected the prefix range end, got %q", rangeEnd)
+	}
+}
+
+func TestCursorRangeDescending(t *testing.T) {
+	startKey, rangeEnd := cursorRange("prefix", &store.ListOptions{
+		StartAfterKey: "prefix/b",
+		SortOrder:     store.SortDescend,
+	})
+
+	if startKey != "prefix" {
+		t.Fatalf("expected startKey to stay at the prefix, got %q", startKey)
+	}
+	if rangeEnd != "prefix/b" {
+		t.Fatalf("expected the range end to stop right before the cursor, got %q", rangeEnd)
+	}
+}
+
+func TestCursorRangeNoCursor(t *testing.T) {
+	startKey, rangeEnd := cursorRange("prefix", nil)
+
+	if startKey != "prefix" {
+		t.Fatalf("expected startKey to be the prefix itself, got %q", startKey)
+	}
+	if rangeEnd != "prefiy" {
+		t.Fatalf("expected the prefix range end, got %q", rangeEnd)
+	}
+}
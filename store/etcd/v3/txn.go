@@ -0,0 +1,99 @@
+package etcdv3
+
+import (
+	"context"
+
+	"github.com/kvtools/valkeyrie/store"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// buildTxnOps translates TxnOps into the Compare guards and Then actions of a
+// clientv3.Txn, returning the keys/values needed to build the result KVPairs
+// once the transaction has committed.
+func (s *EtcdV3) buildTxnOps(ops []store.TxnOp) (cmps []clientv3.Cmp, thens []clientv3.Op, thenKeys []string, thenValues [][]byte, err error) {
+	for _, op := range ops {
+		key := s.normalize(op.Key)
+
+		switch op.Kind {
+		case store.OpCheckExists:
+			// As with OpPut/AtomicPut, Previous == nil expects the key
+			// to be absent; a non-nil Previous expects it to be present.
+			if op.Previous == nil {
+				cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(key), "=", 0))
+			} else {
+				cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(key), ">", 0))
+			}
+
+		case store.OpCheckValue:
+			if op.Previous == nil {
+				return nil, nil, nil, nil, store.ErrPreviousNotSpecified
+			}
+			cmps = append(cmps, clientv3.Compare(clientv3.Value(key), "=", string(op.Previous.Value)))
+
+		case store.OpCheckIndex:
+			if op.Previous == nil {
+				return nil, nil, nil, nil, store.ErrPreviousNotSpecified
+			}
+			cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", int64(op.Previous.LastIndex)))
+
+		case store.OpPut:
+			if op.Previous == nil {
+				cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(key), "=", 0))
+			} else {
+				cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", int64(op.Previous.LastIndex)))
+			}
+
+			putOpts, err := s.ttlOpts(&store.WriteOptions{TTL: op.TTL})
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+
+			thens = append(thens, clientv3.OpPut(key, string(op.Value), putOpts...))
+			thenKeys = append(thenKeys, op.Key)
+			thenValues = append(thenValues, op.Value)
+
+		case store.OpDelete:
+			if op.Previous != nil {
+				cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", int64(op.Previous.LastIndex)))
+			}
+
+			thens = append(thens, clientv3.OpDelete(key))
+			thenKeys = append(thenKeys, op.Key)
+			thenValues = append(thenValues, nil)
+		}
+	}
+
+	return cmps, thens, thenKeys, thenValues, nil
+}
+
+// AtomicBatch executes ops as a single clientv3.Txn: every check op becomes a
+// Compare guard, and every Put/Delete op becomes a Then action plus a guard:
+// on its Previous if it carries one, or, for a Put with a nil Previous, on the
+// key being absent (a Delete with a nil Previous is unguarded). If any guard fails, the whole batch is
+// rejected with ErrKeyModified rather than partially applied.
+func (s *EtcdV3) AtomicBatch(ops []store.TxnOp) (bool, []*store.KVPair, error) {
+	cmps, thens, thenKeys, thenValues, err := s.buildTxnOps(ops)
+	if err != nil {
+		return false, nil, err
+	}
+
+	resp, err := s.client.Txn(context.Background()).If(cmps...).Then(thens...).Commit()
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !resp.Succeeded {
+		return false, nil, store.ErrKeyModified
+	}
+
+	results := make([]*store.KVPair, 0, len(thenKeys))
+	for i, key := range thenKeys {
+		results = append(results, &store.KVPair{
+			Key:       key,
+			Value:     thenValues[i],
+			LastIndex: uint64(resp.Header.Revision),
+		})
+	}
+
+	return true, results, nil
+}
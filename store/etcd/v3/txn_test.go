@@ -0,0 +1,57 @@
+package etcdv3
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kvtools/valkeyrie/store"
+)
+
+func TestBuildTxnOpsCheckValueRequiresPrevious(t *testing.T) {
+	s := &EtcdV3{}
+
+	_, _, _, _, err := s.buildTxnOps([]store.TxnOp{
+		{Kind: store.OpCheckValue, Key: "foo"},
+	})
+
+	if err != store.ErrPreviousNotSpecified {
+		t.Fatalf("expected ErrPreviousNotSpecified, got %v", err)
+	}
+}
+
+func TestBuildTxnOpsCheckIndexRequiresPrevious(t *testing.T) {
+	s := &EtcdV3{}
+
+	_, _, _, _, err := s.buildTxnOps([]store.TxnOp{
+		{Kind: store.OpCheckIndex, Key: "foo"},
+	})
+
+	if err != store.ErrPreviousNotSpecified {
+		t.Fatalf("expected ErrPreviousNotSpecified, got %v", err)
+	}
+}
+
+func TestBuildTxnOpsCheckExistsPolarity(t *testing.T) {
+	s := &EtcdV3{}
+
+	cmps, _, _, _, err := s.buildTxnOps([]store.TxnOp{
+		{Kind: store.OpCheckExists, Key: "absent"},
+		{Kind: store.OpCheckExists, Key: "present", Previous: &store.KVPair{Key: "present"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cmps) != 2 {
+		t.Fatalf("expected 2 comparisons, got %d", len(cmps))
+	}
+
+	// A nil Previous must guard on absence ("="), matching OpPut's convention
+	// for a fresh key; a non-nil Previous must guard on presence (">").
+	if got := fmt.Sprintf("%v", cmps[0].Result); !strings.Contains(got, "EQUAL") {
+		t.Fatalf("expected the absent-guard comparison to check equality to 0, got %q", got)
+	}
+	if got := fmt.Sprintf("%v", cmps[1].Result); !strings.Contains(got, "GREATER") {
+		t.Fatalf("expected the present-guard comparison to check greater-than 0, got %q", got)
+	}
+}
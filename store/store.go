@@ -0,0 +1,253 @@
+// Package store contains the store interface and the types shared by all backends.
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Backend represents a KV Store backend.
+type Backend string
+
+const (
+	// ETCD backend, using the deprecated etcd v2 (HTTP/JSON) API.
+	ETCD Backend = "etcd"
+	// ETCDV3 backend, using the etcd v3 (gRPC) API.
+	ETCDV3 Backend = "etcdv3"
+)
+
+var (
+	// ErrBackendNotSupported is thrown when the backend k/v store is not supported by valkeyrie.
+	ErrBackendNotSupported = errors.New("backend storage not supported yet, please choose another one")
+
+	// ErrCallNotSupported is thrown when a method is not implemented/supported by the current backend.
+	ErrCallNotSupported = errors.New("the current call is not supported with this backend")
+
+	// ErrNotImplemented is thrown when a method is not implemented by the current backend.
+	ErrNotImplemented = errors.New("call not implemented in current backend")
+
+	// ErrKeyModified is thrown during an atomic operation if the index does not match the one in the store.
+	ErrKeyModified = errors.New("unable to complete atomic operation, key modified")
+
+	// ErrKeyNotFound is thrown when the key is not found in the store during a Get operation.
+	ErrKeyNotFound = errors.New("key not found in store")
+
+	// ErrPreviousNotSpecified is thrown when an atomic operation is called without an existing previous value.
+	ErrPreviousNotSpecified = errors.New("previous K/V pair should be provided for the Atomic operation")
+
+	// ErrKeyExists is thrown when the previous value exists in the case of an AtomicPut.
+	ErrKeyExists = errors.New("previous K/V pair exists, cannot complete Atomic operation")
+
+	// ErrWatchCompacted is reported by a backend's WatchErr once a Watch/WatchTree
+	// channel has closed because the requested starting index/revision is no
+	// longer available (etcd v2's "index cleared" and v3's "required revision
+	// has been compacted" errors). Callers should
+	// resync from scratch rather than resume from their last known position.
+	ErrWatchCompacted = errors.New("watch history requested is no longer available, resync required")
+)
+
+// Config contains the options for a storage client.
+type Config struct {
+	TLS               *tls.Config
+	ConnectionTimeout time.Duration
+	SyncPeriod        time.Duration
+	Username          string
+	Password          string
+}
+
+// ReadOptions contains the options used by Get, List, Watch and WatchTree.
+type ReadOptions struct {
+	// Consistent forces a quorum read of the value.
+	Consistent bool
+
+	// StartIndex resumes a Watch/WatchTree after this index (etcd v2's ModifiedIndex)
+	// instead of sending an initial snapshot. Ignored if zero.
+	StartIndex uint64
+
+	// StartRevision resumes a Watch/WatchTree after this revision (etcd v3's
+	// ModRevision) instead of sending an initial snapshot. Ignored if zero.
+	StartRevision int64
+}
+
+// WriteOptions contains the options used by Put.
+type WriteOptions struct {
+	IsDir bool
+	TTL   time.Duration
+}
+
+// LockOptions contains the options used by NewLock.
+type LockOptions struct {
+	Value     []byte        // Optional, value to associate with the lock
+	TTL       time.Duration // Optional, expiration ttl associated with the lock
+	RenewLock chan struct{} // Optional, chan used to control and stop the session ttl renewal for the lock
+}
+
+// KVPair represents {Key, Value, Lastindex} tuple.
+type KVPair struct {
+	Key       string
+	Value     []byte
+	LastIndex uint64
+}
+
+// SortOrder controls the ordering of keys returned by ListPrefix.
+type SortOrder int
+
+const (
+	// SortNone leaves the order unspecified.
+	SortNone SortOrder = iota
+	// SortAscend orders keys lexicographically, ascending.
+	SortAscend
+	// SortDescend orders keys lexicographically, descending.
+	SortDescend
+)
+
+// ListOptions contains the options used by ListPrefix.
+type ListOptions struct {
+	// Limit caps the number of keys returned. Zero means unlimited.
+	Limit int64
+
+	// StartAfterKey resumes listing right after this key, for cursor-style pagination.
+	StartAfterKey string
+
+	// KeysOnly skips fetching values, returning KVPairs with a nil Value.
+	KeysOnly bool
+
+	// SortOrder controls the order in which keys are returned.
+	SortOrder SortOrder
+}
+
+// Store represents the backend K/V storage.
+// Each store should support every call listed here.
+// Or it couldn't be implemented as a K/V backend for valkeyrie.
+type Store interface {
+	// Put a value at the specified key.
+	Put(key string, value []byte, options *WriteOptions) error
+
+	// Get a value given its key.
+	Get(key string, options *ReadOptions) (*KVPair, error)
+
+	// Delete the value at the specified key.
+	Delete(key string) error
+
+	// Exists verifies if a key exists in the store.
+	Exists(key string, options *ReadOptions) (bool, error)
+
+	// Watch for changes on a key.
+	Watch(key string, stopCh <-chan struct{}, options *ReadOptions) (<-chan *KVPair, error)
+
+	// WatchTree watches for changes on child nodes under a directory.
+	WatchTree(directory string, stopCh <-chan struct{}, options *ReadOptions) (<-chan []*KVPair, error)
+
+	// NewLock creates a lock for a given key.
+	// The returned Locker is not held and must be acquired with Lock.
+	NewLock(key string, options *LockOptions) (Locker, error)
+
+	// List the content of a given prefix.
+	List(directory string, options *ReadOptions) ([]*KVPair, error)
+
+	// DeleteTree deletes a range of keys under a given directory.
+	DeleteTree(directory string) error
+
+	// AtomicPut puts a value at "key" if the key has not been
+	// modified in the meantime, throws an error if this is the case.
+	AtomicPut(key string, value []byte, previous *KVPair, options *WriteOptions) (bool, *KVPair, error)
+
+	// AtomicDelete deletes a value at "key" if the key has not
+	// been modified in the meantime, throws an error if this is the case.
+	AtomicDelete(key string, previous *KVPair) (bool, error)
+
+	// AtomicBatch executes a list of operations as a single transaction:
+	// either every check passes and every write commits, or nothing does.
+	// Backends that cannot offer this guarantee return ErrCallNotSupported.
+	AtomicBatch(ops []TxnOp) (bool, []*KVPair, error)
+
+	// ListPrefix lists the keys under a given prefix, with optional pagination.
+	// On backends with a native range API (etcd v3), unlike List, prefix is
+	// matched directly against keys rather than treated as a directory of
+	// child nodes; etcd v2 has no such API and treats prefix as a directory.
+	ListPrefix(prefix string, options *ListOptions) ([]*KVPair, error)
+
+	// DeletePrefix deletes every key under a given prefix.
+	DeletePrefix(prefix string) error
+
+	// Count returns the number of keys under a given prefix.
+	Count(prefix string) (int64, error)
+
+	// Close the store connection.
+	Close()
+}
+
+// TxnOpKind identifies the kind of operation carried by a TxnOp.
+type TxnOpKind int
+
+const (
+	// OpPut writes Value at Key, guarded by Previous; a nil Previous
+	// expects Key to be absent.
+	OpPut TxnOpKind = iota
+	// OpDelete removes Key, optionally guarded by Previous.
+	OpDelete
+	// OpCheckExists guards the transaction on whether Key currently exists.
+	// As with OpPut, Previous == nil expects Key to be absent and a
+	// non-nil Previous expects it to be present.
+	OpCheckExists
+	// OpCheckValue guards the transaction on Key's current value matching Previous.Value.
+	OpCheckValue
+	// OpCheckIndex guards the transaction on Key's current LastIndex matching Previous.LastIndex.
+	OpCheckIndex
+)
+
+// TxnOp is a single operation within an AtomicBatch.
+type TxnOp struct {
+	Kind     TxnOpKind
+	Key      string
+	Value    []byte
+	TTL      time.Duration
+	Previous *KVPair
+}
+
+// Locker provides locking mechanism on top of the store.
+// Calls to Lock block until the lock is acquired or an error occurs.
+type Locker interface {
+	Lock(stopChan chan struct{}) (<-chan struct{}, error)
+	Unlock() error
+}
+
+// Elector provides leader election on top of the store.
+// Unlike a Locker, an Elector exposes the ordered candidacy and the
+// current leader's value to every participant, not just the winner.
+type Elector interface {
+	// Campaign puts the candidate's value forward for election and blocks
+	// until it becomes the leader. It returns a channel that is closed
+	// when leadership is lost (e.g. the underlying session expires).
+	Campaign(ctx context.Context, value []byte) (<-chan struct{}, error)
+
+	// Resign gives up leadership, if held, allowing the next candidate to be elected.
+	Resign(ctx context.Context) error
+
+	// Leader returns the current leader's KVPair, or ErrKeyNotFound if there is none.
+	Leader(ctx context.Context) (*KVPair, error)
+
+	// Observe streams the leader's KVPair every time it changes.
+	Observe(ctx context.Context) <-chan *KVPair
+
+	// Close releases the resources (e.g. the underlying session/lease) backing
+	// this Elector. Call it once the caller is done campaigning, even after Resign.
+	Close() error
+}
+
+// Normalize the key for usage in the K/V store.
+func Normalize(key string) string {
+	return "/" + strings.TrimPrefix(key, "/")
+}
+
+// CreateEndpoints creates a list of endpoints given the right scheme.
+func CreateEndpoints(addrs []string, scheme string) []string {
+	entries := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		entries = append(entries, scheme+"://"+addr)
+	}
+	return entries
+}
@@ -0,0 +1,24 @@
+// Package valkeyrie provides a library for storing key/value pairs in a
+// distributed K/V store, with a single interface for multiple backends.
+package valkeyrie
+
+import "github.com/kvtools/valkeyrie/store"
+
+// Initialize creates a new Store object, initializing the client.
+type Initialize func(addrs []string, options *store.Config) (store.Store, error)
+
+// initializers for all supported backends.
+var initializers = make(map[store.Backend]Initialize)
+
+// AddStore adds a new backend store to the list of initializers.
+func AddStore(backend store.Backend, init Initialize) {
+	initializers[backend] = init
+}
+
+// NewStore creates a new Store object, initializing the client based on the given backend.
+func NewStore(backend store.Backend, addrs []string, options *store.Config) (store.Store, error) {
+	if init, exists := initializers[backend]; exists {
+		return init(addrs, options)
+	}
+	return nil, store.ErrBackendNotSupported
+}